@@ -0,0 +1,200 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/golang/glog"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+// LoadConfigFile parses a CMServer's tuning knobs from the YAML file at
+// path. The schema mirrors the CMServer struct field-for-field (see the
+// `yaml` struct tags). Alongside the parsed CMServer it returns the set of
+// top-level YAML keys the file actually contained, keyed by their `yaml`
+// tag name -- callers need that to tell "the file left this field at its
+// Go zero value" apart from "the file didn't mention this field at all".
+func LoadConfigFile(path string) (*CMServer, map[string]bool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := &CMServer{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, nil, fmt.Errorf("error parsing %q: %v", path, err)
+	}
+
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("error parsing %q: %v", path, err)
+	}
+	present := make(map[string]bool, len(raw))
+	for key := range raw {
+		present[key] = true
+	}
+	return config, present, nil
+}
+
+// loadConfigFile applies s.ConfigFile, if set, onto s. A field is only
+// taken from the file when the corresponding flag was not explicitly
+// passed on the command line, so flags always win.
+func (s *CMServer) loadConfigFile() error {
+	if s.ConfigFile == "" {
+		return nil
+	}
+	fileConfig, present, err := LoadConfigFile(s.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("error loading --config %q: %v", s.ConfigFile, err)
+	}
+	mergeFlagDefaults(s, fileConfig, present, s.flags)
+	return nil
+}
+
+// yamlKeyName returns the key a CMServer field would be read from in a
+// --config file, i.e. the part of its `yaml` tag before any comma-separated
+// option such as ",omitempty".
+func yamlKeyName(field reflect.StructField) string {
+	return strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+}
+
+// mergeFlagDefaults copies every `flag`-tagged field of fileConfig onto s
+// that was actually present in the file, skipping any whose flag the user
+// passed explicitly -- those always win over the file. A field the file
+// simply didn't mention is left untouched, rather than zeroed out.
+func mergeFlagDefaults(s, fileConfig *CMServer, present map[string]bool, flags *pflag.FlagSet) {
+	if flags == nil {
+		return
+	}
+	sv := reflect.ValueOf(s).Elem()
+	fv := reflect.ValueOf(fileConfig).Elem()
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		flagName := field.Tag.Get("flag")
+		if flagName == "" || flags.Changed(flagName) || !present[yamlKeyName(field)] {
+			continue
+		}
+		sv.Field(i).Set(fv.Field(i))
+	}
+}
+
+// reloadableFields are the CMServer fields that the controllers they tune
+// can safely pick up after they have already started -- everything else
+// (cloud provider, service-account signing) requires a restart and is
+// logged and ignored instead.
+var reloadableFields = []string{
+	"ConcurrentEndpointSyncs",
+	"ConcurrentRCSyncs",
+	"NodeSyncPeriod",
+	"ResourceQuotaSyncPeriod",
+	"NamespaceSyncPeriod",
+	"PVClaimBinderSyncPeriod",
+	"DeletingPodsQps",
+	"DeletingPodsBurst",
+	"PodEvictionTimeout",
+}
+
+// watchConfigFile watches s.ConfigFile's directory (so that atomic renames,
+// as used by ConfigMap-mounted files, are picked up) and re-applies the
+// reloadableFields subset on every change. It returns once stop is closed.
+func (s *CMServer) watchConfigFile(stop <-chan struct{}) {
+	if s.ConfigFile == "" {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		glog.Errorf("Error creating --config watcher, hot reload disabled: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(filepath.Dir(s.ConfigFile)); err != nil {
+		glog.Errorf("Error watching %q, hot reload disabled: %v", s.ConfigFile, err)
+		return
+	}
+
+	target := filepath.Clean(s.ConfigFile)
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != target || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			s.reloadConfigFile()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			glog.Errorf("Error watching %q: %v", s.ConfigFile, err)
+		}
+	}
+}
+
+// reloadConfigFile re-reads s.ConfigFile, applies the reloadableFields
+// subset to s, warns about any other field the file changed, and asks
+// every controller that registered a ReloadFunc to pick up the new values.
+func (s *CMServer) reloadConfigFile() {
+	fileConfig, present, err := LoadConfigFile(s.ConfigFile)
+	if err != nil {
+		glog.Errorf("Error reloading --config %q, keeping the previous configuration: %v", s.ConfigFile, err)
+		return
+	}
+
+	sv := reflect.ValueOf(s).Elem()
+	fv := reflect.ValueOf(fileConfig).Elem()
+	st := sv.Type()
+	reloadable := map[string]bool{}
+	for _, name := range reloadableFields {
+		reloadable[name] = true
+	}
+
+	changed := false
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if field.Tag.Get("flag") == "" || !present[yamlKeyName(field)] {
+			continue
+		}
+		if reflect.DeepEqual(sv.Field(i).Interface(), fv.Field(i).Interface()) {
+			continue
+		}
+		if !reloadable[field.Name] {
+			glog.Warningf("Ignoring change to %q on reload; restart the controller-manager to pick it up", field.Name)
+			continue
+		}
+		sv.Field(i).Set(fv.Field(i))
+		changed = true
+	}
+
+	if !changed {
+		return
+	}
+	glog.Infof("Reloaded %q", s.ConfigFile)
+	reloadAll(s)
+}
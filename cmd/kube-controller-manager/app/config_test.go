@@ -0,0 +1,99 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func newTestFlagSet(s *CMServer) *pflag.FlagSet {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.IntVar(&s.Port, "port", s.Port, "")
+	fs.IntVar(&s.ConcurrentRCSyncs, "concurrent_rc_syncs", s.ConcurrentRCSyncs, "")
+	fs.DurationVar(&s.NodeSyncPeriod, "node-sync-period", s.NodeSyncPeriod, "")
+	return fs
+}
+
+func TestMergeFlagDefaultsOnlyAppliesPresentKeys(t *testing.T) {
+	s := &CMServer{Port: 10252, ConcurrentRCSyncs: 5, NodeSyncPeriod: 10 * time.Second}
+	s.flags = newTestFlagSet(s)
+
+	// The file only mentions concurrentRCSyncs; port and nodeSyncPeriod are
+	// absent, not explicitly zeroed, and must be left alone.
+	fileConfig := &CMServer{ConcurrentRCSyncs: 42}
+	present := map[string]bool{"concurrentRCSyncs": true}
+
+	mergeFlagDefaults(s, fileConfig, present, s.flags)
+
+	if s.ConcurrentRCSyncs != 42 {
+		t.Errorf("ConcurrentRCSyncs = %d, want 42", s.ConcurrentRCSyncs)
+	}
+	if s.Port != 10252 {
+		t.Errorf("Port = %d, want unchanged 10252, file omitted it", s.Port)
+	}
+	if s.NodeSyncPeriod != 10*time.Second {
+		t.Errorf("NodeSyncPeriod = %s, want unchanged 10s, file omitted it", s.NodeSyncPeriod)
+	}
+}
+
+func TestMergeFlagDefaultsSkipsExplicitFlags(t *testing.T) {
+	s := &CMServer{Port: 10252}
+	s.flags = newTestFlagSet(s)
+	if err := s.flags.Set("port", "9999"); err != nil {
+		t.Fatalf("Set(port): %v", err)
+	}
+
+	fileConfig := &CMServer{Port: 10252}
+	present := map[string]bool{"port": true}
+
+	mergeFlagDefaults(s, fileConfig, present, s.flags)
+
+	if s.Port != 9999 {
+		t.Errorf("Port = %d, want 9999 (explicit flag must win over file)", s.Port)
+	}
+}
+
+func TestMergeFlagDefaultsExplicitZeroInFile(t *testing.T) {
+	s := &CMServer{ConcurrentRCSyncs: 5}
+	s.flags = newTestFlagSet(s)
+
+	// The file explicitly sets concurrentRCSyncs to its zero value; since
+	// the key is present, that should still be honored.
+	fileConfig := &CMServer{ConcurrentRCSyncs: 0}
+	present := map[string]bool{"concurrentRCSyncs": true}
+
+	mergeFlagDefaults(s, fileConfig, present, s.flags)
+
+	if s.ConcurrentRCSyncs != 0 {
+		t.Errorf("ConcurrentRCSyncs = %d, want 0 (file explicitly set it)", s.ConcurrentRCSyncs)
+	}
+}
+
+func TestMergeFlagDefaultsNilFlagSet(t *testing.T) {
+	s := &CMServer{Port: 10252}
+	fileConfig := &CMServer{Port: 1}
+	present := map[string]bool{"port": true}
+
+	mergeFlagDefaults(s, fileConfig, present, nil)
+
+	if s.Port != 10252 {
+		t.Errorf("Port = %d, want unchanged 10252 with a nil flag set", s.Port)
+	}
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/namespace"
+)
+
+func init() {
+	RegisterController("namespace", startNamespaceManager)
+}
+
+func startNamespaceManager(ctx ControllerContext) (bool, error) {
+	namespaceManager := namespace.NewNamespaceManager(ctx.KubeClient, ctx.Options.NamespaceSyncPeriod)
+	namespaceManager.Run(ctx.Stop)
+	RegisterReloadable("namespace", func(s *CMServer) {
+		namespaceManager.SetSyncPeriod(s.NamespaceSyncPeriod)
+	})
+	return true, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"net"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider/nodecontroller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+
+	"github.com/golang/glog"
+)
+
+func init() {
+	RegisterController("node", startNodeController)
+}
+
+func startNodeController(ctx ControllerContext) (bool, error) {
+	s := ctx.Options
+	nodeResources := &api.NodeResources{
+		Capacity: api.ResourceList{
+			api.ResourceCPU:    *resource.NewMilliQuantity(s.NodeMilliCPU, resource.DecimalSI),
+			api.ResourceMemory: s.NodeMemory,
+		},
+	}
+
+	if s.SyncNodeStatus {
+		glog.Warning("DEPRECATION NOTICE: sync-node-status flag is being deprecated. It has no effect now and it will be removed in a future version.")
+	}
+
+	podEvictor := nodecontroller.NewPodEvictor(ctx.KubeClient, util.NewTokenBucketRateLimiter(s.DeletingPodsQps, s.DeletingPodsBurst))
+	if policy, found := nodecontroller.Policies[s.PodEvictorPolicy]; found {
+		podEvictor.SetPolicy(policy)
+	} else {
+		glog.Errorf("Unknown --pod-evictor-policy %q, falling back to the default policy", s.PodEvictorPolicy)
+	}
+	go podEvictor.Run(s.PodEvictorWorkers, ctx.Stop)
+
+	nodeController := nodecontroller.NewNodeController(ctx.Cloud, s.MinionRegexp, s.MachineList, nodeResources,
+		ctx.KubeClient, s.RegisterRetryCount, s.PodEvictionTimeout, podEvictor,
+		s.NodeMonitorGracePeriod, s.NodeStartupGracePeriod, s.NodeMonitorPeriod, (*net.IPNet)(&s.ClusterCIDR), s.AllocateNodeCIDRs)
+	nodeController.Run(s.NodeSyncPeriod, s.SyncNodeList, ctx.Stop)
+
+	RegisterReloadable("node", func(s *CMServer) {
+		podEvictor.SetRateLimiter(util.NewTokenBucketRateLimiter(s.DeletingPodsQps, s.DeletingPodsBurst))
+		nodeController.SetSyncPeriod(s.NodeSyncPeriod)
+		nodeController.SetPodEvictionTimeout(s.PodEvictionTimeout)
+	})
+	return true, nil
+}
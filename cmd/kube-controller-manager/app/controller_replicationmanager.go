@@ -0,0 +1,34 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	replicationControllerPkg "github.com/GoogleCloudPlatform/kubernetes/pkg/controller"
+)
+
+func init() {
+	RegisterController("replicationmanager", startReplicationManager)
+}
+
+func startReplicationManager(ctx ControllerContext) (bool, error) {
+	manager := replicationControllerPkg.NewReplicationManager(ctx.KubeClient, replicationControllerPkg.BurstReplicas)
+	go manager.Run(ctx.Options.ConcurrentRCSyncs, ctx.Stop)
+	RegisterReloadable("replicationmanager", func(s *CMServer) {
+		manager.SetConcurrentSyncs(s.ConcurrentRCSyncs)
+	})
+	return true, nil
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcequota"
+)
+
+func init() {
+	RegisterController("resourcequota", startResourceQuotaManager)
+}
+
+func startResourceQuotaManager(ctx ControllerContext) (bool, error) {
+	resourceQuotaManager := resourcequota.NewResourceQuotaManager(ctx.KubeClient)
+	resourceQuotaManager.Run(ctx.Options.ResourceQuotaSyncPeriod, ctx.Stop)
+	RegisterReloadable("resourcequota", func(s *CMServer) {
+		resourceQuotaManager.SetSyncPeriod(s.ResourceQuotaSyncPeriod)
+	})
+	return true, nil
+}
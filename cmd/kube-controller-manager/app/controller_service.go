@@ -0,0 +1,35 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider/servicecontroller"
+
+	"github.com/golang/glog"
+)
+
+func init() {
+	RegisterController("service", startServiceController)
+}
+
+func startServiceController(ctx ControllerContext) (bool, error) {
+	serviceController := servicecontroller.New(ctx.Cloud, ctx.KubeClient, ctx.Options.ClusterName)
+	if err := serviceController.Run(ctx.Options.NodeSyncPeriod, ctx.Stop); err != nil {
+		glog.Errorf("Failed to start service controller: %v", err)
+	}
+	return true, nil
+}
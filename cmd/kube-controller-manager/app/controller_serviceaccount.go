@@ -0,0 +1,87 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/serviceaccount"
+)
+
+func init() {
+	RegisterController("serviceaccount-token", startServiceAccountTokensController)
+	RegisterController("serviceaccount", startServiceAccountsController)
+}
+
+// startServiceAccountTokensController declines to start, rather than erroring
+// out, when no signing key was configured: running without one is a
+// supported (if insecure) mode for clusters that mint tokens another way.
+// An operator who did configure a signing provider and got the config
+// wrong gets a hard failure instead, so a broken HSM or plugin socket
+// doesn't silently disable token minting cluster-wide.
+func startServiceAccountTokensController(ctx ControllerContext) (bool, error) {
+	s := ctx.Options
+	provider, err := newSigningProvider(s)
+	if err != nil {
+		return false, fmt.Errorf("error setting up service account token signing: %v", err)
+	}
+	if provider == nil {
+		return false, nil
+	}
+
+	serviceaccount.NewTokensController(
+		ctx.KubeClient,
+		serviceaccount.DefaultTokenControllerOptions(
+			serviceaccount.JWTTokenGeneratorFromProvider(provider),
+		),
+	).Run(ctx.Stop)
+	return true, nil
+}
+
+// newSigningProvider builds the SigningProvider selected by
+// --service-account-signing-provider. It returns a nil provider, rather
+// than an error, when running in the default file mode without a key file
+// configured: that combination means the token controller is intentionally
+// disabled.
+func newSigningProvider(s *CMServer) (serviceaccount.SigningProvider, error) {
+	switch s.ServiceAccountSigningProvider {
+	case "", "file":
+		if len(s.ServiceAccountKeyFile) == 0 {
+			return nil, nil
+		}
+		return serviceaccount.NewFileSigningProvider(s.ServiceAccountKeyFile)
+	case "pkcs11":
+		return serviceaccount.NewPKCS11SigningProvider(serviceaccount.PKCS11Config{
+			ModulePath: s.ServiceAccountSigningPKCS11Module,
+			Slot:       s.ServiceAccountSigningPKCS11Slot,
+			KeyLabel:   s.ServiceAccountSigningPKCS11KeyLabel,
+			PIN:        s.ServiceAccountSigningPKCS11PIN,
+		})
+	case "plugin":
+		return serviceaccount.NewPluginSigningProvider(s.ServiceAccountSigningPluginSocket)
+	default:
+		return nil, fmt.Errorf("unknown --service-account-signing-provider %q", s.ServiceAccountSigningProvider)
+	}
+}
+
+func startServiceAccountsController(ctx ControllerContext) (bool, error) {
+	serviceaccount.NewServiceAccountsController(
+		ctx.KubeClient,
+		serviceaccount.DefaultServiceAccountsControllerOptions(),
+	).Run(ctx.Stop)
+	return true, nil
+}
@@ -20,97 +20,132 @@ limitations under the License.
 package app
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client/clientcmd"
 	clientcmdapi "github.com/GoogleCloudPlatform/kubernetes/pkg/client/clientcmd/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client/leaderelection"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider/nodecontroller"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider/servicecontroller"
-	replicationControllerPkg "github.com/GoogleCloudPlatform/kubernetes/pkg/controller"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/healthz"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/master/ports"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/namespace"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/resourcequota"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/service"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/serviceaccount"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/volumeclaimbinder"
 
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/pflag"
 )
 
-// CMServer is the main context object for the controller manager.
+// CMServer is the main context object for the controller manager. Its
+// exported fields can be set by flag, by --config YAML file, or both --
+// see AddFlags and LoadConfigFile. The `flag` tag names the flag that wins
+// over a file-provided value; fields without one are flag- or CLI-only and
+// are not read from the config file.
 type CMServer struct {
-	Port                    int
-	Address                 util.IP
-	CloudProvider           string
-	CloudConfigFile         string
-	ConcurrentEndpointSyncs int
-	ConcurrentRCSyncs       int
-	MinionRegexp            string
-	NodeSyncPeriod          time.Duration
-	ResourceQuotaSyncPeriod time.Duration
-	NamespaceSyncPeriod     time.Duration
-	PVClaimBinderSyncPeriod time.Duration
-	RegisterRetryCount      int
+	Port                    int           `yaml:"port" flag:"port"`
+	Address                 util.IP       `yaml:"address" flag:"address"`
+	CloudProvider           string        `yaml:"cloudProvider" flag:"cloud-provider"`
+	CloudConfigFile         string        `yaml:"cloudConfigFile" flag:"cloud-config"`
+	ConcurrentEndpointSyncs int           `yaml:"concurrentEndpointSyncs" flag:"concurrent-endpoint-syncs"`
+	ConcurrentRCSyncs       int           `yaml:"concurrentRCSyncs" flag:"concurrent_rc_syncs"`
+	MinionRegexp            string        `yaml:"minionRegexp" flag:"minion-regexp"`
+	NodeSyncPeriod          time.Duration `yaml:"nodeSyncPeriod" flag:"node-sync-period"`
+	ResourceQuotaSyncPeriod time.Duration `yaml:"resourceQuotaSyncPeriod" flag:"resource-quota-sync-period"`
+	NamespaceSyncPeriod     time.Duration `yaml:"namespaceSyncPeriod" flag:"namespace-sync-period"`
+	PVClaimBinderSyncPeriod time.Duration `yaml:"pvClaimBinderSyncPeriod" flag:"pvclaimbinder-sync-period"`
+	RegisterRetryCount      int           `yaml:"registerRetryCount" flag:"register-retry-count"`
 	MachineList             util.StringList
-	SyncNodeList            bool
-	SyncNodeStatus          bool
-	NodeMonitorGracePeriod  time.Duration
-	NodeStartupGracePeriod  time.Duration
-	NodeMonitorPeriod       time.Duration
+	SyncNodeList            bool          `yaml:"syncNodeList" flag:"sync-nodes"`
+	SyncNodeStatus          bool          `yaml:"syncNodeStatus" flag:"sync-node-status"`
+	NodeMonitorGracePeriod  time.Duration `yaml:"nodeMonitorGracePeriod" flag:"node-monitor-grace-period"`
+	NodeStartupGracePeriod  time.Duration `yaml:"nodeStartupGracePeriod" flag:"node-startup-grace-period"`
+	NodeMonitorPeriod       time.Duration `yaml:"nodeMonitorPeriod" flag:"node-monitor-period"`
 	NodeStatusUpdateRetry   int
-	PodEvictionTimeout      time.Duration
-	DeletingPodsQps         float32
-	DeletingPodsBurst       int
-	ServiceAccountKeyFile   string
+	PodEvictionTimeout      time.Duration `yaml:"podEvictionTimeout" flag:"pod-eviction-timeout"`
+	DeletingPodsQps         float32       `yaml:"deletingPodsQps" flag:"deleting-pods-qps"`
+	DeletingPodsBurst       int           `yaml:"deletingPodsBurst" flag:"deleting-pods-burst"`
+	PodEvictorPolicy        string        `yaml:"podEvictorPolicy" flag:"pod-evictor-policy"`
+	PodEvictorWorkers       int           `yaml:"podEvictorWorkers" flag:"pod-evictor-workers"`
+	ServiceAccountKeyFile   string        `yaml:"serviceAccountKeyFile" flag:"service-account-private-key-file"`
+	Controllers             util.StringList
+	ShutdownTimeout         time.Duration `yaml:"shutdownTimeout" flag:"shutdown-timeout"`
+
+	ServiceAccountSigningProvider       string `yaml:"serviceAccountSigningProvider" flag:"service-account-signing-provider"`
+	ServiceAccountSigningPluginSocket   string `yaml:"serviceAccountSigningPluginSocket" flag:"service-account-signing-plugin-socket"`
+	ServiceAccountSigningPKCS11Module   string `yaml:"serviceAccountSigningPKCS11Module" flag:"service-account-signing-pkcs11-module"`
+	ServiceAccountSigningPKCS11Slot     uint   `yaml:"serviceAccountSigningPKCS11Slot" flag:"service-account-signing-pkcs11-slot"`
+	ServiceAccountSigningPKCS11KeyLabel string `yaml:"serviceAccountSigningPKCS11KeyLabel" flag:"service-account-signing-pkcs11-key-label"`
+	ServiceAccountSigningPKCS11PIN      string `yaml:"serviceAccountSigningPKCS11PIN" flag:"service-account-signing-pkcs11-pin"`
+
+	LeaderElect              bool          `yaml:"leaderElect" flag:"leader-elect"`
+	LeaderElectLeaseDuration time.Duration `yaml:"leaderElectLeaseDuration" flag:"leader-elect-lease-duration"`
+	LeaderElectRenewDeadline time.Duration `yaml:"leaderElectRenewDeadline" flag:"leader-elect-renew-deadline"`
+	LeaderElectRetryPeriod   time.Duration `yaml:"leaderElectRetryPeriod" flag:"leader-elect-retry-period"`
+	LeaderElectNamespace     string        `yaml:"leaderElectNamespace" flag:"leader-elect-resource-namespace"`
 
 	// TODO: Discover these by pinging the host machines, and rip out these params.
 	NodeMilliCPU int64
 	NodeMemory   resource.Quantity
 
-	ClusterName       string
+	ClusterName       string `yaml:"clusterName" flag:"cluster-name"`
 	ClusterCIDR       util.IPNet
-	AllocateNodeCIDRs bool
-	EnableProfiling   bool
+	AllocateNodeCIDRs bool `yaml:"allocateNodeCIDRs" flag:"allocate-node-cidrs"`
+	EnableProfiling   bool `yaml:"enableProfiling" flag:"profiling"`
 
 	Master     string
 	Kubeconfig string
+
+	// ConfigFile, if set, provides defaults for any of the `flag`-tagged
+	// fields above that were not also passed as a flag; flags always take
+	// precedence. See LoadConfigFile.
+	ConfigFile string
+
+	flags *pflag.FlagSet
 }
 
 // NewCMServer creates a new CMServer with a default config.
 func NewCMServer() *CMServer {
 	s := CMServer{
-		Port:                    ports.ControllerManagerPort,
-		Address:                 util.IP(net.ParseIP("127.0.0.1")),
-		ConcurrentEndpointSyncs: 5,
-		ConcurrentRCSyncs:       5,
-		NodeSyncPeriod:          10 * time.Second,
-		ResourceQuotaSyncPeriod: 10 * time.Second,
-		NamespaceSyncPeriod:     5 * time.Minute,
-		PVClaimBinderSyncPeriod: 10 * time.Second,
-		RegisterRetryCount:      10,
-		PodEvictionTimeout:      5 * time.Minute,
-		NodeMilliCPU:            1000,
-		NodeMemory:              resource.MustParse("3Gi"),
-		SyncNodeList:            true,
-		ClusterName:             "kubernetes",
+		Port:                          ports.ControllerManagerPort,
+		Address:                       util.IP(net.ParseIP("127.0.0.1")),
+		ConcurrentEndpointSyncs:       5,
+		ConcurrentRCSyncs:             5,
+		NodeSyncPeriod:                10 * time.Second,
+		ResourceQuotaSyncPeriod:       10 * time.Second,
+		NamespaceSyncPeriod:           5 * time.Minute,
+		PVClaimBinderSyncPeriod:       10 * time.Second,
+		RegisterRetryCount:            10,
+		PodEvictionTimeout:            5 * time.Minute,
+		PodEvictorPolicy:              "default",
+		PodEvictorWorkers:             1,
+		NodeMilliCPU:                  1000,
+		NodeMemory:                    resource.MustParse("3Gi"),
+		SyncNodeList:                  true,
+		ClusterName:                   "kubernetes",
+		LeaderElectLeaseDuration:      15 * time.Second,
+		LeaderElectRenewDeadline:      10 * time.Second,
+		LeaderElectRetryPeriod:        2 * time.Second,
+		LeaderElectNamespace:          "kube-system",
+		ShutdownTimeout:               15 * time.Second,
+		ServiceAccountSigningProvider: "file",
 	}
 	return &s
 }
 
 // AddFlags adds flags for a specific CMServer to the specified FlagSet
 func (s *CMServer) AddFlags(fs *pflag.FlagSet) {
+	s.flags = fs
+	fs.StringVar(&s.ConfigFile, "config", s.ConfigFile, "Path to a YAML file providing defaults for the flags below. An explicit flag always overrides the value from this file. A subset of fields (the concurrent sync counts, the sync periods, the deleting-pods QPS and burst, and the pod eviction timeout) are hot-reloaded on change without restarting the process.")
 	fs.IntVar(&s.Port, "port", s.Port, "The port that the controller-manager's http service runs on")
 	fs.Var(&s.Address, "address", "The IP address to serve on (set to 0.0.0.0 for all interfaces)")
 	fs.StringVar(&s.CloudProvider, "cloud-provider", s.CloudProvider, "The provider for cloud services.  Empty string for no provider.")
@@ -127,6 +162,8 @@ func (s *CMServer) AddFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&s.PodEvictionTimeout, "pod-eviction-timeout", s.PodEvictionTimeout, "The grace peroid for deleting pods on failed nodes.")
 	fs.Float32Var(&s.DeletingPodsQps, "deleting-pods-qps", 0.1, "Number of nodes per second on which pods are deleted in case of node failure.")
 	fs.IntVar(&s.DeletingPodsBurst, "deleting-pods-burst", 10, "Number of nodes on which pods are bursty deleted in case of node failure. For more details look into RateLimiter.")
+	fs.StringVar(&s.PodEvictorPolicy, "pod-evictor-policy", s.PodEvictorPolicy, "The eviction policy the PodEvictor uses to decide whether to delete the pods on an unhealthy node. One of: default.")
+	fs.IntVar(&s.PodEvictorWorkers, "pod-evictor-workers", s.PodEvictorWorkers, "The number of workers processing the PodEvictor's eviction queue.")
 	fs.IntVar(&s.RegisterRetryCount, "register-retry-count", s.RegisterRetryCount, ""+
 		"The number of retries for initial node registration.  Retry interval equals node-sync-period.")
 	fs.Var(&s.MachineList, "machines", "List of machines to schedule onto, comma separated.")
@@ -142,6 +179,16 @@ func (s *CMServer) AddFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&s.NodeMonitorPeriod, "node-monitor-period", 5*time.Second,
 		"The period for syncing NodeStatus in NodeController.")
 	fs.StringVar(&s.ServiceAccountKeyFile, "service-account-private-key-file", s.ServiceAccountKeyFile, "Filename containing a PEM-encoded private RSA key used to sign service account tokens.")
+	fs.StringVar(&s.ServiceAccountSigningProvider, "service-account-signing-provider", s.ServiceAccountSigningProvider, "How the service account token controller signs tokens. One of: file (sign with --service-account-private-key-file), pkcs11 (sign with a key held in an HSM), plugin (sign through an external signer process). Defaults to file.")
+	fs.StringVar(&s.ServiceAccountSigningPluginSocket, "service-account-signing-plugin-socket", s.ServiceAccountSigningPluginSocket, "Unix socket of the external signer plugin. Only used when --service-account-signing-provider=plugin.")
+	fs.StringVar(&s.ServiceAccountSigningPKCS11Module, "service-account-signing-pkcs11-module", s.ServiceAccountSigningPKCS11Module, "Path to the PKCS#11 module used to reach the signing HSM. Only used when --service-account-signing-provider=pkcs11.")
+	fs.UintVar(&s.ServiceAccountSigningPKCS11Slot, "service-account-signing-pkcs11-slot", s.ServiceAccountSigningPKCS11Slot, "PKCS#11 slot holding the signing key. Only used when --service-account-signing-provider=pkcs11.")
+	fs.StringVar(&s.ServiceAccountSigningPKCS11KeyLabel, "service-account-signing-pkcs11-key-label", s.ServiceAccountSigningPKCS11KeyLabel, "CKA_LABEL of the signing key object in the PKCS#11 slot. Only used when --service-account-signing-provider=pkcs11.")
+	fs.StringVar(&s.ServiceAccountSigningPKCS11PIN, "service-account-signing-pkcs11-pin", s.ServiceAccountSigningPKCS11PIN, "PIN used to log in to the PKCS#11 slot. Only used when --service-account-signing-provider=pkcs11.")
+	fs.Var(&s.Controllers, "controllers", ""+
+		"A list of controllers to enable, prefixed with '+' to enable or '-' to disable alongside the default set, "+
+		"or without a prefix to run only the named controllers. '*' denotes the default set. Comma separated. "+
+		"Known controllers are: "+strings.Join(knownControllerNames(), ", ")+". Defaults to '*'.")
 	// TODO: Discover these by pinging the host machines, and rip out these flags.
 	// TODO: in the meantime, use resource.QuantityFlag() instead of these
 	fs.Int64Var(&s.NodeMilliCPU, "node-milli-cpu", s.NodeMilliCPU, "The amount of MilliCPU provisioned on each node")
@@ -151,6 +198,12 @@ func (s *CMServer) AddFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&s.AllocateNodeCIDRs, "allocate-node-cidrs", false, "Should CIDRs for Pods be allocated and set on the cloud provider.")
 	fs.StringVar(&s.Master, "master", s.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig)")
 	fs.StringVar(&s.Kubeconfig, "kubeconfig", s.Kubeconfig, "Path to kubeconfig file with authorization and master location information.")
+	fs.BoolVar(&s.LeaderElect, "leader-elect", s.LeaderElect, "Start a leader election client and gain leadership before executing the main loop. Enable this when running replicated controller-manager pods for high availability.")
+	fs.DurationVar(&s.LeaderElectLeaseDuration, "leader-elect-lease-duration", s.LeaderElectLeaseDuration, "The duration that non-leader candidates will wait after observing a leadership renewal until attempting to acquire leadership of a led but unrenewed leader slot. This is effectively the maximum duration that a leader can be stopped before it is replaced by another candidate.")
+	fs.DurationVar(&s.LeaderElectRenewDeadline, "leader-elect-renew-deadline", s.LeaderElectRenewDeadline, "The duration that the acting master keeps retrying to renew a leadership slot before it stops leading. This must be less than the lease duration.")
+	fs.DurationVar(&s.LeaderElectRetryPeriod, "leader-elect-retry-period", s.LeaderElectRetryPeriod, "The duration the clients should wait between attempting acquisition and renewal of a leadership.")
+	fs.StringVar(&s.LeaderElectNamespace, "leader-elect-resource-namespace", s.LeaderElectNamespace, "The namespace of the Endpoints object used as the leader election lock.")
+	fs.DurationVar(&s.ShutdownTimeout, "shutdown-timeout", s.ShutdownTimeout, "The maximum amount of time to wait for in-flight requests to drain after receiving a shutdown signal, before the HTTP server is forcibly closed.")
 }
 
 func (s *CMServer) verifyMinionFlags() {
@@ -170,6 +223,9 @@ func (s *CMServer) verifyMinionFlags() {
 
 // Run runs the CMServer.  This should never exit.
 func (s *CMServer) Run(_ []string) error {
+	if err := s.loadConfigFile(); err != nil {
+		return err
+	}
 	s.verifyMinionFlags()
 
 	if s.Kubeconfig == "" && s.Master == "" {
@@ -193,79 +249,130 @@ func (s *CMServer) Run(_ []string) error {
 		glog.Fatalf("Invalid API configuration: %v", err)
 	}
 
-	go func() {
-		mux := http.NewServeMux()
-		healthz.InstallHandler(mux)
-		if s.EnableProfiling {
-			mux.HandleFunc("/debug/pprof/", pprof.Index)
-			mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-			mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-		}
-		mux.Handle("/metrics", prometheus.Handler())
+	mux := http.NewServeMux()
+	healthz.InstallHandler(mux)
+	if s.EnableProfiling {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	}
+	mux.Handle("/metrics", prometheus.Handler())
 
-		server := &http.Server{
-			Addr:    net.JoinHostPort(s.Address.String(), strconv.Itoa(s.Port)),
-			Handler: mux,
+	server := &http.Server{
+		Addr:    net.JoinHostPort(s.Address.String(), strconv.Itoa(s.Port)),
+		Handler: mux,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			glog.Fatal(err)
 		}
-		glog.Fatal(server.ListenAndServe())
 	}()
 
-	endpoints := service.NewEndpointController(kubeClient)
-	go endpoints.Run(s.ConcurrentEndpointSyncs, util.NeverStop)
+	// stop is closed once on the first SIGINT/SIGTERM, giving every
+	// controller's Run a chance to return before the process exits. A
+	// second signal forces an immediate exit for operators in a hurry.
+	// This is distinct from the leader election loop below: losing
+	// leadership stops the controllers running under that lease, but the
+	// process keeps serving /healthz and /metrics and keeps trying to
+	// reacquire the lease until stop is closed.
+	stop := make(chan struct{})
+	shutdownSignals := make(chan os.Signal, 2)
+	signal.Notify(shutdownSignals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-shutdownSignals
+		glog.Infof("Received shutdown signal, stopping controllers")
+		close(stop)
+		<-shutdownSignals
+		glog.Fatalf("Received second shutdown signal, exiting immediately")
+	}()
 
-	controllerManager := replicationControllerPkg.NewReplicationManager(kubeClient, replicationControllerPkg.BurstReplicas)
-	go controllerManager.Run(s.ConcurrentRCSyncs, util.NeverStop)
+	go s.watchConfigFile(stop)
 
-	cloud := cloudprovider.InitCloudProvider(s.CloudProvider, s.CloudConfigFile)
-	nodeResources := &api.NodeResources{
-		Capacity: api.ResourceList{
-			api.ResourceCPU:    *resource.NewMilliQuantity(s.NodeMilliCPU, resource.DecimalSI),
-			api.ResourceMemory: s.NodeMemory,
-		},
+	run := func(stop <-chan struct{}) {
+		s.runControllers(kubeClient, stop)
 	}
 
-	if s.SyncNodeStatus {
-		glog.Warning("DEPRECATION NOTICE: sync-node-status flag is being deprecated. It has no effect now and it will be removed in a future version.")
-	}
+	if !s.LeaderElect {
+		run(stop)
+	} else {
+		id, err := os.Hostname()
+		if err != nil {
+			return err
+		}
 
-	nodeController := nodecontroller.NewNodeController(cloud, s.MinionRegexp, s.MachineList, nodeResources,
-		kubeClient, s.RegisterRetryCount, s.PodEvictionTimeout, util.NewTokenBucketRateLimiter(s.DeletingPodsQps, s.DeletingPodsBurst),
-		s.NodeMonitorGracePeriod, s.NodeStartupGracePeriod, s.NodeMonitorPeriod, (*net.IPNet)(&s.ClusterCIDR), s.AllocateNodeCIDRs)
-	nodeController.Run(s.NodeSyncPeriod, s.SyncNodeList)
+		leaderElector, err := leaderelection.NewLeaderElector(leaderelection.Config{
+			Client:             kubeClient,
+			EndpointsNamespace: s.LeaderElectNamespace,
+			EndpointsName:      "kube-controller-manager",
+			Identity:           id,
+			LeaseDuration:      s.LeaderElectLeaseDuration,
+			RenewDeadline:      s.LeaderElectRenewDeadline,
+			RetryPeriod:        s.LeaderElectRetryPeriod,
+			OnStartedLeading:   run,
+			OnStoppedLeading: func() {
+				glog.Infof("Lost leadership, stopping controllers and waiting to reacquire")
+			},
+		})
+		if err != nil {
+			return err
+		}
 
-	serviceController := servicecontroller.New(cloud, kubeClient, s.ClusterName)
-	if err := serviceController.Run(s.NodeSyncPeriod); err != nil {
-		glog.Errorf("Failed to start service controller: %v", err)
+		// leaderElector.Run returns once this replica loses a lease it held,
+		// or once stop is closed; until it acquires the lease it keeps
+		// retrying inside Run. Loop back into it after a loss to keep trying
+		// to reacquire the lease as a non-leader replica until stop is
+		// closed by a shutdown signal.
+	retryLeaderElection:
+		for {
+			leaderElector.Run(stop)
+			select {
+			case <-stop:
+				break retryLeaderElection
+			default:
+			}
+		}
 	}
 
-	resourceQuotaManager := resourcequota.NewResourceQuotaManager(kubeClient)
-	resourceQuotaManager.Run(s.ResourceQuotaSyncPeriod)
+	<-stop
+	glog.Infof("Shutting down HTTP server (timeout %s)", s.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		glog.Errorf("Error shutting down HTTP server: %v", err)
+	}
+	return nil
+}
 
-	namespaceManager := namespace.NewNamespaceManager(kubeClient, s.NamespaceSyncPeriod)
-	namespaceManager.Run()
+// runControllers constructs and starts every enabled controller owned by the
+// controller-manager. It returns once stop is closed, which callers use as
+// the hook for cleanly stepping down on loss of leadership.
+func (s *CMServer) runControllers(kubeClient *client.Client, stop <-chan struct{}) {
+	enabled, err := ControllersToRun(s.Controllers)
+	if err != nil {
+		glog.Fatalf("Failed to resolve --controllers: %v", err)
+	}
 
-	pvclaimBinder := volumeclaimbinder.NewPersistentVolumeClaimBinder(kubeClient, s.PVClaimBinderSyncPeriod)
-	pvclaimBinder.Run()
+	cloud := cloudprovider.InitCloudProvider(s.CloudProvider, s.CloudConfigFile)
+	ctx := ControllerContext{
+		Options:    s,
+		KubeClient: kubeClient,
+		Cloud:      cloud,
+		Stop:       stop,
+	}
 
-	if len(s.ServiceAccountKeyFile) > 0 {
-		privateKey, err := serviceaccount.ReadPrivateKey(s.ServiceAccountKeyFile)
+	for name, initFn := range Registry {
+		if !enabled[name] {
+			glog.Infof("Skipping controller %q", name)
+			continue
+		}
+		glog.Infof("Starting controller %q", name)
+		started, err := initFn(ctx)
 		if err != nil {
-			glog.Errorf("Error reading key for service account token controller: %v", err)
-		} else {
-			serviceaccount.NewTokensController(
-				kubeClient,
-				serviceaccount.DefaultTokenControllerOptions(
-					serviceaccount.JWTTokenGenerator(privateKey),
-				),
-			).Run()
+			glog.Fatalf("Error starting controller %q: %v", name, err)
+		}
+		if !started {
+			glog.Infof("Controller %q declined to start", name)
 		}
 	}
-
-	serviceaccount.NewServiceAccountsController(
-		kubeClient,
-		serviceaccount.DefaultServiceAccountsControllerOptions(),
-	).Run()
-
-	select {}
-	return nil
+	<-stop
 }
@@ -0,0 +1,136 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider"
+
+	"github.com/golang/glog"
+)
+
+// ControllerContext holds everything an InitFunc needs to start its
+// controller: the shared API client and cloud provider, the CMServer's
+// parsed options, and the stop channel that signals shutdown or loss of
+// leadership.
+type ControllerContext struct {
+	Options    *CMServer
+	KubeClient *client.Client
+	Cloud      cloudprovider.Interface
+	Stop       <-chan struct{}
+}
+
+// InitFunc starts a single controller from a ControllerContext. It returns
+// started=false with a nil error when the controller intentionally declined
+// to start (for example because a prerequisite flag was left empty), and a
+// non-nil error when startup failed outright.
+type InitFunc func(ctx ControllerContext) (started bool, err error)
+
+// Registry is the set of controllers known to this binary, keyed by name.
+// Built-in controllers add themselves from an init() function in their own
+// file; vendors can add their own controllers the same way by compiling an
+// extra file into this package.
+var Registry = map[string]InitFunc{}
+
+// RegisterController adds name to the Registry. It panics on a duplicate
+// name, since that means two controllers were compiled in under the same
+// name.
+func RegisterController(name string, initFn InitFunc) {
+	if _, found := Registry[name]; found {
+		panic(fmt.Sprintf("controller %q was registered twice", name))
+	}
+	Registry[name] = initFn
+}
+
+// ReloadFunc re-applies whatever tuning knobs an already-running controller
+// can safely pick up from s without being restarted.
+type ReloadFunc func(s *CMServer)
+
+// reloadRegistry holds the ReloadFunc a controller's InitFunc registered for
+// it, keyed by the same name it was registered under in Registry. Not every
+// controller has one: only those with a live knob listed in
+// config.go's reloadableFields.
+var reloadRegistry = map[string]ReloadFunc{}
+
+// RegisterReloadable lets an InitFunc expose a ReloadFunc for the controller
+// it just started, so that a --config file change can re-tune it without a
+// restart. Call it once per InitFunc invocation, after the controller is
+// constructed.
+func RegisterReloadable(name string, reload ReloadFunc) {
+	reloadRegistry[name] = reload
+}
+
+// reloadAll invokes every registered ReloadFunc with the freshly reloaded s.
+func reloadAll(s *CMServer) {
+	for name, reload := range reloadRegistry {
+		glog.Infof("Reloading tunables for controller %q", name)
+		reload(s)
+	}
+}
+
+// knownControllerNames returns the names in the Registry, sorted, for use in
+// flag help text.
+func knownControllerNames() []string {
+	names := make([]string, 0, len(Registry))
+	for name := range Registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ControllersToRun resolves a --controllers selection (e.g. "*,-service,+foo")
+// against the Registry and returns the set of controller names that should
+// run. An empty selection means "run everything".
+func ControllersToRun(selection []string) (map[string]bool, error) {
+	if len(selection) == 0 {
+		selection = []string{"*"}
+	}
+
+	enabled := map[string]bool{}
+	for _, token := range selection {
+		token = strings.TrimSpace(token)
+		switch {
+		case token == "*":
+			for name := range Registry {
+				enabled[name] = true
+			}
+		case strings.HasPrefix(token, "-"):
+			name := token[1:]
+			if _, found := Registry[name]; !found {
+				return nil, fmt.Errorf("unknown controller %q", name)
+			}
+			enabled[name] = false
+		case strings.HasPrefix(token, "+"):
+			name := token[1:]
+			if _, found := Registry[name]; !found {
+				return nil, fmt.Errorf("unknown controller %q", name)
+			}
+			enabled[name] = true
+		default:
+			if _, found := Registry[token]; !found {
+				return nil, fmt.Errorf("unknown controller %q", token)
+			}
+			enabled[token] = true
+		}
+	}
+	return enabled, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+// withRegistry swaps the package-level Registry for the duration of fn, so
+// tests don't depend on (or pollute) whatever controllers real init()
+// functions have registered.
+func withRegistry(names []string, fn func()) {
+	old := Registry
+	Registry = map[string]InitFunc{}
+	for _, name := range names {
+		Registry[name] = func(ControllerContext) (bool, error) { return true, nil }
+	}
+	defer func() { Registry = old }()
+	fn()
+}
+
+func TestControllersToRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection []string
+		want      map[string]bool
+		wantErr   bool
+	}{
+		{
+			name:      "empty selection means everything",
+			selection: nil,
+			want:      map[string]bool{"node": true, "service": true, "endpoint": true},
+		},
+		{
+			name:      "star means everything",
+			selection: []string{"*"},
+			want:      map[string]bool{"node": true, "service": true, "endpoint": true},
+		},
+		{
+			name:      "star with a minus disables one",
+			selection: []string{"*", "-service"},
+			want:      map[string]bool{"node": true, "service": false, "endpoint": true},
+		},
+		{
+			name:      "bare name list with no star enables only those named",
+			selection: []string{"node", "endpoint"},
+			want:      map[string]bool{"node": true, "endpoint": true},
+		},
+		{
+			name:      "plus re-enables after a minus, last token wins",
+			selection: []string{"*", "-service", "+service"},
+			want:      map[string]bool{"node": true, "service": true, "endpoint": true},
+		},
+		{
+			name:      "surrounding whitespace is ignored",
+			selection: []string{" node ", " -service "},
+			want:      map[string]bool{"node": true, "service": false},
+		},
+		{
+			name:      "unknown bare name is an error",
+			selection: []string{"bogus"},
+			wantErr:   true,
+		},
+		{
+			name:      "unknown name with a minus prefix is an error",
+			selection: []string{"*", "-bogus"},
+			wantErr:   true,
+		},
+		{
+			name:      "unknown name with a plus prefix is an error",
+			selection: []string{"+bogus"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRegistry([]string{"node", "service", "endpoint"}, func() {
+				got, err := ControllersToRun(tt.selection)
+				if tt.wantErr {
+					if err == nil {
+						t.Fatalf("ControllersToRun(%v) = nil error, want an error", tt.selection)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("ControllersToRun(%v) = %v, want no error", tt.selection, err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("ControllersToRun(%v) = %v, want %v", tt.selection, got, tt.want)
+				}
+			})
+		})
+	}
+}
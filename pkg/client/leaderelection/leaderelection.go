@@ -0,0 +1,248 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package leaderelection implements a simple lease-based leader election
+// scheme on top of an Endpoints object, so that several replicas of a
+// component (e.g. the controller manager) can run at once while only one
+// of them is active.
+package leaderelection
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+
+	"github.com/golang/glog"
+)
+
+// leaderElectionRecordAnnotationKey is the Endpoints annotation that stores
+// the current leader's identity and lease bookkeeping.
+const leaderElectionRecordAnnotationKey = "control-plane.alpha.kubernetes.io/leader"
+
+// LeaderElectionRecord is stored as JSON in the leaderElectionRecordAnnotationKey
+// annotation of the lock Endpoints object.
+type LeaderElectionRecord struct {
+	HolderIdentity       string    `json:"holderIdentity"`
+	LeaseDurationSeconds int       `json:"leaseDurationSeconds"`
+	AcquireTime          time.Time `json:"acquireTime"`
+	RenewTime            time.Time `json:"renewTime"`
+}
+
+// Config configures a LeaderElector.
+type Config struct {
+	// Client is used to read and update the lock Endpoints object.
+	Client client.Interface
+
+	// EndpointsNamespace/EndpointsName identify the Endpoints object used as the lock.
+	EndpointsNamespace string
+	EndpointsName      string
+
+	// Identity is this replica's unique name, stamped into the lock while it holds leadership.
+	Identity string
+
+	// LeaseDuration is how long a leader's lease is valid for after its last renewal.
+	LeaseDuration time.Duration
+	// RenewDeadline is how long the current leader will retry refreshing leadership before giving up.
+	RenewDeadline time.Duration
+	// RetryPeriod is how long a non-leader waits between attempts to acquire the lock.
+	RetryPeriod time.Duration
+
+	// OnStartedLeading is called when this replica starts leading.
+	OnStartedLeading func(stop <-chan struct{})
+	// OnStoppedLeading is called when Run returns: when this replica stops
+	// leading, or when Run is stopped before it ever acquired the lease.
+	OnStoppedLeading func()
+}
+
+func (c *Config) validate() error {
+	if c.Client == nil {
+		return fmt.Errorf("Config.Client is required")
+	}
+	if c.EndpointsNamespace == "" || c.EndpointsName == "" {
+		return fmt.Errorf("Config.EndpointsNamespace and Config.EndpointsName are required")
+	}
+	if c.Identity == "" {
+		return fmt.Errorf("Config.Identity is required")
+	}
+	if c.LeaseDuration <= c.RenewDeadline {
+		return fmt.Errorf("LeaseDuration must be greater than RenewDeadline")
+	}
+	if c.RenewDeadline <= time.Duration(JitterFactor*float64(c.RetryPeriod)) {
+		return fmt.Errorf("RenewDeadline must be greater than RetryPeriod*JitterFactor")
+	}
+	return nil
+}
+
+// JitterFactor bounds how far RenewDeadline must exceed RetryPeriod, matching the
+// jitter applied when retrying lock acquisition.
+const JitterFactor = 1.2
+
+// LeaderElector runs a leader election loop against a Config's lock Endpoints object.
+type LeaderElector struct {
+	config Config
+
+	observedRecord LeaderElectionRecord
+	observedTime   time.Time
+}
+
+// NewLeaderElector creates a LeaderElector from the given Config.
+func NewLeaderElector(config Config) (*LeaderElector, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &LeaderElector{config: config}, nil
+}
+
+// Run starts the leader election loop. It blocks until stopCh is closed or,
+// once the lease has been acquired, until leadership is lost.
+// While it holds the lease it invokes OnStartedLeading with a channel that
+// closes the moment leadership is lost. OnStoppedLeading is called whenever
+// Run returns, including when stopCh closes before the lease was acquired.
+func (le *LeaderElector) Run(stopCh <-chan struct{}) {
+	defer func() {
+		if le.config.OnStoppedLeading != nil {
+			le.config.OnStoppedLeading()
+		}
+	}()
+
+	if !le.acquire(stopCh) {
+		return // stopCh was closed before we acquired the lock
+	}
+
+	leaderStop := make(chan struct{})
+	go func() {
+		defer close(leaderStop)
+		le.renewLoop(stopCh)
+	}()
+
+	if le.config.OnStartedLeading != nil {
+		le.config.OnStartedLeading(leaderStop)
+	}
+	<-leaderStop
+}
+
+// acquire blocks, retrying on RetryPeriod, until it becomes leader or stopCh closes.
+func (le *LeaderElector) acquire(stopCh <-chan struct{}) bool {
+	for {
+		select {
+		case <-stopCh:
+			return false
+		default:
+		}
+		if le.tryAcquireOrRenew() {
+			glog.Infof("Successfully acquired lease %s/%s", le.config.EndpointsNamespace, le.config.EndpointsName)
+			return true
+		}
+		select {
+		case <-stopCh:
+			return false
+		case <-time.After(util.Jitter(le.config.RetryPeriod, JitterFactor-1.0)):
+		}
+	}
+}
+
+// renewLoop keeps renewing the lease until it fails to do so within RenewDeadline or stopCh closes.
+func (le *LeaderElector) renewLoop(stopCh <-chan struct{}) {
+	deadline := time.Now().Add(le.config.RenewDeadline)
+	for time.Now().Before(deadline) {
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(le.config.RetryPeriod):
+		}
+		if le.tryAcquireOrRenew() {
+			deadline = time.Now().Add(le.config.RenewDeadline)
+			continue
+		}
+		glog.Warningf("Failed to renew lease %s/%s, retrying until the renew deadline", le.config.EndpointsNamespace, le.config.EndpointsName)
+	}
+	glog.Errorf("Renew deadline exceeded for lease %s/%s, giving up leadership", le.config.EndpointsNamespace, le.config.EndpointsName)
+}
+
+// tryAcquireOrRenew is a single attempt to either take a free/expired lock or
+// renew one this replica already holds.
+func (le *LeaderElector) tryAcquireOrRenew() bool {
+	now := time.Now()
+	endpoints, err := le.config.Client.Endpoints(le.config.EndpointsNamespace).Get(le.config.EndpointsName)
+	if errors.IsNotFound(err) {
+		endpoints = &api.Endpoints{
+			ObjectMeta: api.ObjectMeta{
+				Name:      le.config.EndpointsName,
+				Namespace: le.config.EndpointsNamespace,
+			},
+		}
+		err = nil
+	}
+	if err != nil {
+		glog.Errorf("Error retrieving lease %s/%s: %v", le.config.EndpointsNamespace, le.config.EndpointsName, err)
+		return false
+	}
+
+	record := LeaderElectionRecord{
+		HolderIdentity:       le.config.Identity,
+		LeaseDurationSeconds: int(le.config.LeaseDuration / time.Second),
+		AcquireTime:          now,
+		RenewTime:            now,
+	}
+
+	if existing, found := endpoints.Annotations[leaderElectionRecordAnnotationKey]; found {
+		var observed LeaderElectionRecord
+		if err := json.Unmarshal([]byte(existing), &observed); err == nil {
+			le.observedRecord = observed
+			held := observed.HolderIdentity == le.config.Identity
+			expired := now.Sub(observed.RenewTime) > time.Duration(observed.LeaseDurationSeconds)*time.Second
+			if !held && !expired {
+				return false
+			}
+			if held {
+				record.AcquireTime = observed.AcquireTime
+			}
+		}
+	}
+
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		glog.Errorf("Error marshaling lease record: %v", err)
+		return false
+	}
+	if endpoints.Annotations == nil {
+		endpoints.Annotations = map[string]string{}
+	}
+	endpoints.Annotations[leaderElectionRecordAnnotationKey] = string(recordBytes)
+
+	if endpoints.ResourceVersion == "" {
+		_, err = le.config.Client.Endpoints(le.config.EndpointsNamespace).Create(endpoints)
+	} else {
+		_, err = le.config.Client.Endpoints(le.config.EndpointsNamespace).Update(endpoints)
+	}
+	if err != nil {
+		glog.Errorf("Error updating lease %s/%s: %v", le.config.EndpointsNamespace, le.config.EndpointsName, err)
+		return false
+	}
+	le.observedRecord = record
+	le.observedTime = now
+	return true
+}
+
+// IsLeader returns whether this replica currently believes it holds the lease.
+func (le *LeaderElector) IsLeader() bool {
+	return le.observedRecord.HolderIdentity == le.config.Identity
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+)
+
+func validConfig() Config {
+	return Config{
+		Client:             &client.Client{},
+		EndpointsNamespace: "kube-system",
+		EndpointsName:      "kube-controller-manager",
+		Identity:           "host-1",
+		LeaseDuration:      15 * time.Second,
+		RenewDeadline:      10 * time.Second,
+		RetryPeriod:        2 * time.Second,
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(c *Config)
+		wantErr bool
+	}{
+		{name: "valid config", mutate: func(c *Config) {}, wantErr: false},
+		{name: "missing client", mutate: func(c *Config) { c.Client = nil }, wantErr: true},
+		{name: "missing endpoints namespace", mutate: func(c *Config) { c.EndpointsNamespace = "" }, wantErr: true},
+		{name: "missing endpoints name", mutate: func(c *Config) { c.EndpointsName = "" }, wantErr: true},
+		{name: "missing identity", mutate: func(c *Config) { c.Identity = "" }, wantErr: true},
+		{
+			name:    "lease duration not greater than renew deadline",
+			mutate:  func(c *Config) { c.LeaseDuration = c.RenewDeadline },
+			wantErr: true,
+		},
+		{
+			name: "renew deadline too close to retry period*jitter",
+			mutate: func(c *Config) {
+				c.RenewDeadline = time.Duration(JitterFactor * float64(c.RetryPeriod))
+			},
+			wantErr: true,
+		},
+		{
+			name: "renew deadline comfortably above retry period*jitter",
+			mutate: func(c *Config) {
+				c.RetryPeriod = 1 * time.Second
+				c.RenewDeadline = 5 * time.Second
+				c.LeaseDuration = 10 * time.Second
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.mutate(&c)
+			err := c.validate()
+			if tt.wantErr && err == nil {
+				t.Errorf("validate() = nil, want an error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("validate() = %v, want no error", err)
+			}
+		})
+	}
+}
+
+func TestNewLeaderElectorRejectsInvalidConfig(t *testing.T) {
+	c := validConfig()
+	c.Identity = ""
+	if _, err := NewLeaderElector(c); err == nil {
+		t.Error("NewLeaderElector() = nil error, want an error for an invalid Config")
+	}
+}
+
+func TestIsLeaderBeforeAcquiring(t *testing.T) {
+	le, err := NewLeaderElector(validConfig())
+	if err != nil {
+		t.Fatalf("NewLeaderElector() = %v", err)
+	}
+	if le.IsLeader() {
+		t.Error("IsLeader() = true before any lease has ever been observed")
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodecontroller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/fields"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// deferredReevaluationPeriod is how long a node that's deferred (or that hit
+// the rate limiter) waits before it's handed back to a worker, so that a
+// single unhealthy or throttled node doesn't keep a worker spinning on it.
+const deferredReevaluationPeriod = 5 * time.Second
+
+// queueSize bounds how many nodes can be waiting for a worker at once.
+// Evict drops a node, with a log, rather than blocking the caller when the
+// queue is this full -- a healthy cluster never gets close to it.
+const queueSize = 4096
+
+// EvictionDecision is returned by a PolicyFunc to tell the PodEvictor what,
+// if anything, it should do about the pods running on a node.
+type EvictionDecision int
+
+const (
+	// EvictionDecisionNone means the node is healthy and nothing should happen to its pods.
+	EvictionDecisionNone EvictionDecision = iota
+	// EvictionDecisionEvict means the pods on the node should be deleted.
+	EvictionDecisionEvict
+	// EvictionDecisionDefer means the node looks unhealthy, but the policy wants to wait
+	// before evicting (e.g. to respect a PodDisruptionBudget or a grace period).
+	EvictionDecisionDefer
+)
+
+// PolicyFunc decides what should happen to the pods on a node. It is the
+// extension point operators use to plug in taint-based, PodDisruptionBudget-aware,
+// or workload-priority eviction policies.
+type PolicyFunc func(node *api.Node) EvictionDecision
+
+// DefaultPolicy evicts pods from any node reported as not ready, matching the
+// historical behavior of the node controller's built-in eviction logic.
+func DefaultPolicy(node *api.Node) EvictionDecision {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == api.NodeReady && condition.Status != api.ConditionTrue {
+			return EvictionDecisionEvict
+		}
+	}
+	return EvictionDecisionNone
+}
+
+// Policies is the registry of named eviction policies that can be selected
+// with the --pod-evictor-policy flag.
+var Policies = map[string]PolicyFunc{
+	"default": DefaultPolicy,
+}
+
+var (
+	evictionsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pod_evictor_evictions_total",
+		Help: "Number of node pod evictions performed by the PodEvictor.",
+	})
+	evictionErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pod_evictor_eviction_errors_total",
+		Help: "Number of errors encountered while evicting pods from a node.",
+	})
+	queueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "pod_evictor_queue_depth",
+		Help: "Number of nodes currently queued for eviction.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(evictionsTotal)
+	prometheus.MustRegister(evictionErrors)
+	prometheus.MustRegister(queueDepth)
+}
+
+// PodEvictor owns the queue and retry loop that evict pods from nodes the
+// configured policy judges unhealthy. It runs independently of the node
+// health monitor so that eviction policy can evolve (taints, PDBs, workload
+// priority) without touching node status collection.
+type PodEvictor struct {
+	client      client.Interface
+	rateLimiter util.RateLimiter
+
+	lock   sync.Mutex
+	policy PolicyFunc
+
+	queue chan *api.Node
+}
+
+// NewPodEvictor creates a PodEvictor that deletes pods through client,
+// throttling evictions with rateLimiter. The policy defaults to
+// DefaultPolicy; call SetPolicy to override it.
+func NewPodEvictor(client client.Interface, rateLimiter util.RateLimiter) *PodEvictor {
+	return &PodEvictor{
+		client:      client,
+		rateLimiter: rateLimiter,
+		policy:      DefaultPolicy,
+		queue:       make(chan *api.Node, queueSize),
+	}
+}
+
+// SetPolicy overrides the policy used to decide whether a node's pods should be evicted.
+func (pe *PodEvictor) SetPolicy(policy PolicyFunc) {
+	pe.lock.Lock()
+	defer pe.lock.Unlock()
+	pe.policy = policy
+}
+
+// SetRateLimiter swaps the rate limiter throttling evictions, so that
+// --deleting-pods-qps/--deleting-pods-burst can be retuned without
+// restarting the worker goroutines already reading pe.rateLimiter.
+func (pe *PodEvictor) SetRateLimiter(rateLimiter util.RateLimiter) {
+	pe.lock.Lock()
+	defer pe.lock.Unlock()
+	pe.rateLimiter = rateLimiter
+}
+
+// Evict enqueues node for evaluation by the current policy. It is safe to call
+// from the node health monitor's goroutine.
+func (pe *PodEvictor) Evict(node *api.Node) {
+	select {
+	case pe.queue <- node:
+		queueDepth.Set(float64(len(pe.queue)))
+	default:
+		glog.Errorf("Pod evictor queue is full (%d), dropping node %s", queueSize, node.Name)
+	}
+}
+
+// evictAfter re-enqueues node once d has passed, instead of immediately, so
+// a deferred or rate-limited node doesn't keep a worker spinning on it.
+func (pe *PodEvictor) evictAfter(node *api.Node, d time.Duration) {
+	time.AfterFunc(d, func() { pe.Evict(node) })
+}
+
+// Run starts workers workers pulling nodes off the eviction queue until stopCh is closed.
+func (pe *PodEvictor) Run(workers int, stopCh <-chan struct{}) {
+	for i := 0; i < workers; i++ {
+		go pe.runWorker(stopCh)
+	}
+	<-stopCh
+}
+
+// runWorker pulls nodes off the queue, blocking whenever it's empty, until
+// stopCh is closed.
+func (pe *PodEvictor) runWorker(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case node := <-pe.queue:
+			queueDepth.Set(float64(len(pe.queue)))
+			pe.worker(node)
+		}
+	}
+}
+
+func (pe *PodEvictor) worker(node *api.Node) {
+	decision := pe.currentPolicy()(node)
+	switch decision {
+	case EvictionDecisionEvict:
+		if !pe.currentRateLimiter().TryAccept() {
+			pe.evictAfter(node, deferredReevaluationPeriod)
+			return
+		}
+		if err := pe.evictPods(node); err != nil {
+			glog.Errorf("Error evicting pods from node %s: %v", node.Name, err)
+			evictionErrors.Inc()
+			return
+		}
+		evictionsTotal.Inc()
+	case EvictionDecisionDefer:
+		pe.evictAfter(node, deferredReevaluationPeriod)
+	case EvictionDecisionNone:
+	}
+}
+
+func (pe *PodEvictor) currentPolicy() PolicyFunc {
+	pe.lock.Lock()
+	defer pe.lock.Unlock()
+	return pe.policy
+}
+
+func (pe *PodEvictor) currentRateLimiter() util.RateLimiter {
+	pe.lock.Lock()
+	defer pe.lock.Unlock()
+	return pe.rateLimiter
+}
+
+// evictPods deletes every pod bound to node. A pod that's already gone by
+// the time the delete reaches the API server isn't an error: another
+// worker, or the kubelet itself, may have removed it first.
+func (pe *PodEvictor) evictPods(node *api.Node) error {
+	pods, err := pe.client.Pods(api.NamespaceAll).List(labels.Everything(), fields.OneTermEqualSelector("spec.host", node.Name))
+	if err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if err := pe.client.Pods(pod.Namespace).Delete(pod.Name, nil); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceaccount
+
+import (
+	"fmt"
+)
+
+// SigningProvider abstracts over where a service account token signing key
+// actually lives. The local PEM file implementation is the default, but
+// operators who need a FIPS-compliant or HSM-bound key can plug in the
+// PKCS#11 or external-plugin implementations instead, so that the private
+// key material never has to be loaded into the controller-manager's address
+// space.
+type SigningProvider interface {
+	// Sign returns payload signed with the provider's current private key.
+	Sign(payload []byte) ([]byte, error)
+	// PublicKeys returns every public key the provider can currently verify
+	// with, newest first, so a key rotation can be rolled out to verifiers
+	// before it is used for signing.
+	PublicKeys() []interface{}
+}
+
+// JWTTokenGeneratorFromProvider returns a TokenGenerator that signs tokens
+// through provider instead of holding a raw *rsa.PrivateKey in memory. It is
+// the SigningProvider-based counterpart to JWTTokenGenerator.
+func JWTTokenGeneratorFromProvider(provider SigningProvider) TokenGenerator {
+	return &providerTokenGenerator{provider: provider}
+}
+
+type providerTokenGenerator struct {
+	provider SigningProvider
+}
+
+func (g *providerTokenGenerator) GenerateToken(claims interface{}) (string, error) {
+	payload, err := marshalClaims(claims)
+	if err != nil {
+		return "", err
+	}
+	signature, err := g.provider.Sign(payload)
+	if err != nil {
+		return "", fmt.Errorf("error signing service account token: %v", err)
+	}
+	return encodeJWT(payload, signature), nil
+}
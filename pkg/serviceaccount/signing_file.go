@@ -0,0 +1,50 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceaccount
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+)
+
+// fileSigningProvider signs with a PEM-encoded RSA private key read from
+// disk at construction time. It is the default SigningProvider and matches
+// the behavior the controller-manager had before SigningProvider existed.
+type fileSigningProvider struct {
+	key *rsa.PrivateKey
+}
+
+// NewFileSigningProvider loads the PEM RSA private key at keyFile and
+// returns a SigningProvider backed by it.
+func NewFileSigningProvider(keyFile string) (SigningProvider, error) {
+	key, err := ReadPrivateKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSigningProvider{key: key}, nil
+}
+
+func (p *fileSigningProvider) Sign(payload []byte) ([]byte, error) {
+	hashed := sha256.Sum256(payload)
+	return rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, hashed[:])
+}
+
+func (p *fileSigningProvider) PublicKeys() []interface{} {
+	return []interface{}{&p.key.PublicKey}
+}
@@ -0,0 +1,172 @@
+//go:build pkcs11
+// +build pkcs11
+
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// PKCS#11 support pulls in cgo and a platform PKCS#11 module, so it is
+// built only when the "pkcs11" build tag is set. Binaries that don't need
+// HSM-bound signing keys avoid the cgo dependency entirely.
+package serviceaccount
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// PKCS11Config locates the signing key inside an HSM reachable through a
+// PKCS#11 module.
+type PKCS11Config struct {
+	// ModulePath is the path to the vendor's PKCS#11 shared library.
+	ModulePath string
+	// Slot is the HSM slot number holding the signing key.
+	Slot uint
+	// KeyLabel identifies the key object (CKA_LABEL) within the slot.
+	KeyLabel string
+	// PIN authenticates the session to the slot.
+	PIN string
+}
+
+// pkcs11SigningProvider signs through a key that never leaves the HSM: each
+// Sign call opens a session, finds the key object by label, and asks the
+// module to perform the RSA signature itself.
+type pkcs11SigningProvider struct {
+	ctx    *pkcs11.Ctx
+	config PKCS11Config
+
+	mu        sync.Mutex
+	publicKey *rsa.PublicKey
+}
+
+// NewPKCS11SigningProvider opens config.ModulePath and logs into the
+// configured slot, returning a SigningProvider backed by the HSM-resident
+// key named by config.KeyLabel.
+func NewPKCS11SigningProvider(config PKCS11Config) (SigningProvider, error) {
+	ctx := pkcs11.New(config.ModulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("unable to load PKCS#11 module %q", config.ModulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		return nil, fmt.Errorf("error initializing PKCS#11 module %q: %v", config.ModulePath, err)
+	}
+
+	p := &pkcs11SigningProvider{ctx: ctx, config: config}
+	if _, err := p.publicKeyLocked(); err != nil {
+		ctx.Finalize()
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *pkcs11SigningProvider) session() (pkcs11.SessionHandle, error) {
+	session, err := p.ctx.OpenSession(p.config.Slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return 0, fmt.Errorf("error opening PKCS#11 session on slot %d: %v", p.config.Slot, err)
+	}
+	if err := p.ctx.Login(session, pkcs11.CKU_USER, p.config.PIN); err != nil {
+		p.ctx.CloseSession(session)
+		return 0, fmt.Errorf("error logging in to PKCS#11 slot %d: %v", p.config.Slot, err)
+	}
+	return session, nil
+}
+
+func (p *pkcs11SigningProvider) findKey(session pkcs11.SessionHandle, class uint) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, p.config.KeyLabel),
+	}
+	if err := p.ctx.FindObjectsInit(session, template); err != nil {
+		return 0, err
+	}
+	defer p.ctx.FindObjectsFinal(session)
+
+	objects, _, err := p.ctx.FindObjects(session, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(objects) == 0 {
+		return 0, fmt.Errorf("no PKCS#11 object labeled %q in slot %d", p.config.KeyLabel, p.config.Slot)
+	}
+	return objects[0], nil
+}
+
+// Sign asks the HSM to compute an RSA PKCS#1 v1.5 signature over payload's
+// SHA-256 digest; the private key material never leaves the module.
+func (p *pkcs11SigningProvider) Sign(payload []byte) ([]byte, error) {
+	session, err := p.session()
+	if err != nil {
+		return nil, err
+	}
+	defer p.ctx.CloseSession(session)
+
+	privateKey, err := p.findKey(session, pkcs11.CKO_PRIVATE_KEY)
+	if err != nil {
+		return nil, err
+	}
+
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_SHA256_RSA_PKCS, nil)}
+	if err := p.ctx.SignInit(session, mechanism, privateKey); err != nil {
+		return nil, fmt.Errorf("error initializing PKCS#11 signature: %v", err)
+	}
+	signature, err := p.ctx.Sign(session, payload)
+	if err != nil {
+		return nil, fmt.Errorf("error computing PKCS#11 signature: %v", err)
+	}
+	return signature, nil
+}
+
+func (p *pkcs11SigningProvider) PublicKeys() []interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.publicKey == nil {
+		return nil
+	}
+	return []interface{}{p.publicKey}
+}
+
+func (p *pkcs11SigningProvider) publicKeyLocked() (*rsa.PublicKey, error) {
+	session, err := p.session()
+	if err != nil {
+		return nil, err
+	}
+	defer p.ctx.CloseSession(session)
+
+	handle, err := p.findKey(session, pkcs11.CKO_PUBLIC_KEY)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := p.ctx.GetAttributeValue(session, handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading PKCS#11 public key %q: %v", p.config.KeyLabel, err)
+	}
+
+	publicKey := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(attrs[0].Value),
+		E: int(new(big.Int).SetBytes(attrs[1].Value).Int64()),
+	}
+	p.mu.Lock()
+	p.publicKey = publicKey
+	p.mu.Unlock()
+	return publicKey, nil
+}
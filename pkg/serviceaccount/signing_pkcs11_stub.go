@@ -0,0 +1,46 @@
+//go:build !pkcs11
+// +build !pkcs11
+
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This is the stub half of the PKCS#11 signing provider: it is built
+// whenever the "pkcs11" tag is not set, so that --service-account-signing-provider=pkcs11
+// fails with a clear error at runtime instead of making the default build
+// (which never links the cgo PKCS#11 bindings) fail to compile at all.
+package serviceaccount
+
+import "fmt"
+
+// PKCS11Config locates the signing key inside an HSM reachable through a
+// PKCS#11 module. See signing_pkcs11.go for the fields; this stub only
+// needs the type to exist so callers can reference it unconditionally.
+type PKCS11Config struct {
+	// ModulePath is the path to the vendor's PKCS#11 shared library.
+	ModulePath string
+	// Slot is the HSM slot number holding the signing key.
+	Slot uint
+	// KeyLabel identifies the key object (CKA_LABEL) within the slot.
+	KeyLabel string
+	// PIN authenticates the session to the slot.
+	PIN string
+}
+
+// NewPKCS11SigningProvider always fails: this binary was built without the
+// "pkcs11" tag, so the cgo PKCS#11 bindings aren't linked in.
+func NewPKCS11SigningProvider(config PKCS11Config) (SigningProvider, error) {
+	return nil, fmt.Errorf("PKCS#11 signing support is not compiled into this binary; rebuild with -tags pkcs11")
+}